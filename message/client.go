@@ -1,6 +1,7 @@
 package message
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
@@ -9,10 +10,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/northbright/uuid"
+	"golang.org/x/time/rate"
 )
 
 // Client is used to make HTTP requests of aliyun API message serviices.
@@ -24,6 +27,18 @@ type Client struct {
 	accessKeyID string
 	// accessKeySecret is the access key secret generated by user.
 	accessKeySecret string
+
+	// useHTTPS selects https:// over the default http:// when set via
+	// WithHTTPS.
+	useHTTPS bool
+	// retryPolicy is the RetryPolicy set via WithRetry, or nil to make
+	// every request exactly once.
+	retryPolicy *RetryPolicy
+	// limiter throttles outgoing requests when set via WithRateLimit.
+	limiter *rate.Limiter
+	// endpoints overrides the default host for a service, set via
+	// WithEndpoint.
+	endpoints map[string]string
 }
 
 // Response is the common response for aliyun message services APIs.
@@ -47,15 +62,120 @@ type SingleCallByTTSResponse struct {
 	CallID string `json:"CallId"`
 }
 
+// BatchSMSResponse is the response of HTTP request of sending batch SMS.
+type BatchSMSResponse struct {
+	Response
+}
+
 // NewClient creates a new client.
 //
-// It accepts 2 parameters: access key ID and secret.
-// Both of them are generated by user in aliyun control panel.
-func NewClient(accessKeyID, accessKeySecret string) *Client {
-	return &Client{
+// It accepts 2 required parameters: access key ID and secret, both
+// generated by user in aliyun control panel, plus optional ClientOptions
+// such as WithHTTPS, WithRetry, WithRateLimit and WithEndpoint.
+func NewClient(accessKeyID, accessKeySecret string, opts ...ClientOption) *Client {
+	c := &Client{
 		accessKeyID:     accessKeyID,
 		accessKeySecret: accessKeySecret,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// scheme returns "https" if WithHTTPS was set, "http" otherwise.
+func (c *Client) scheme() string {
+	if c.useHTTPS {
+		return "https"
+	}
+	return "http"
+}
+
+// endpoint returns the host configured for service via WithEndpoint, or
+// defaultHost if none was set.
+func (c *Client) endpoint(service, defaultHost string) string {
+	if host, ok := c.endpoints[service]; ok {
+		return host
+	}
+	return defaultHost
+}
+
+// doRequest signs and sends v against service/defaultHost, retrying per
+// c.retryPolicy (if set) on HTTP 5xx responses or a business Code in the
+// policy's RetryableCodes, and applying c.limiter (if set) before every
+// attempt. It respects ctx's deadline between retries.
+func (c *Client) doRequest(ctx context.Context, httpMethod, service, defaultHost string, v url.Values) ([]byte, error) {
+	sortedQueryStr := v.Encode()
+	sign := c.SignedString(httpMethod, sortedQueryStr)
+	rawQuery := fmt.Sprintf("Signature=%s&%s", sign, sortedQueryStr)
+
+	u := &url.URL{
+		Scheme:   c.scheme(),
+		Host:     c.endpoint(service, defaultHost),
+		Path:     "/",
+		RawQuery: rawQuery,
+	}
+
+	policy := c.retryPolicy
+	if policy == nil {
+		policy = &RetryPolicy{MaxAttempts: 1}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < policy.maxAttempts(); attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		buf, retryable, err := c.attempt(ctx, httpMethod, u, policy)
+		if err == nil {
+			return buf, nil
+		}
+		lastErr = err
+		if !retryable || attempt == policy.maxAttempts()-1 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(policy.delay(attempt)):
+		}
+	}
+	return nil, lastErr
+}
+
+// attempt makes a single HTTP request. The returned bool reports whether
+// a non-nil error is worth retrying.
+func (c *Client) attempt(ctx context.Context, httpMethod string, u *url.URL, policy *RetryPolicy) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, httpMethod, u.String(), nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if resp.StatusCode >= 500 {
+		return nil, true, fmt.Errorf("message: server error: %d", resp.StatusCode)
+	}
+
+	var r Response
+	if err := json.Unmarshal(buf, &r); err == nil && policy.isRetryableCode(r.Code) {
+		return nil, true, fmt.Errorf("message: retryable error code: %s", r.Code)
+	}
+
+	return buf, false, nil
 }
 
 // SpecialURLEncode follows aliyun's POP protocol to do special URL encoding.
@@ -106,12 +226,15 @@ func (c *Client) SignedString(httpMethod, sortedQueryStr string) string {
 //
 // It returns success status, response and error.
 //
+// ctx governs the request's lifetime, including waits between retries
+// when the client was created with WithRetry.
+//
 // For example:
 //
 // c := message.NewClient(accessKeyID, accessKeySecret)
 //
-// ok, resp, err := c.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234","product":"ytx"}`)
-func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, error) {
+// ok, resp, err := c.SendSMS(context.Background(), []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234","product":"ytx"}`)
+func (c *Client) SendSMS(ctx context.Context, phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, error) {
 	v := url.Values{}
 	// Set default common parameters for aliyun services.
 	c.SetDefaultCommonParams(v)
@@ -132,41 +255,93 @@ func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, template
 		param.f(v)
 	}
 
-	// Get sorted query string by keys.
-	sortedQueryStr := v.Encode()
+	buf, err := c.doRequest(ctx, "GET", "sms", "dysmsapi.aliyuncs.com", v)
+	if err != nil {
+		return false, nil, err
+	}
 
-	// Get signature.
-	sign := c.SignedString("GET", sortedQueryStr)
+	// Parse JSON response
+	response := &SMSResponse{}
+	if err = json.Unmarshal(buf, response); err != nil {
+		return false, nil, err
+	}
 
-	// Make final query string with signature.
-	rawQuery := fmt.Sprintf("Signature=%s&%s", sign, sortedQueryStr)
+	if strings.ToUpper(response.Code) != "OK" {
+		return false, response, nil
+	}
+	return true, response, nil
+}
 
-	// New a URL with host, raw query.
-	u := &url.URL{
-		Scheme:   "http",
-		Host:     "dysmsapi.aliyuncs.com",
-		Path:     "/",
-		RawQuery: rawQuery,
+// SendBatchSMS sends personalized SMS to many phone numbers in a single call.
+//
+// Unlike SendSMS, each phone number may have its own signature name and
+// template parameters: phoneNumbers, signNames and templateParams must
+// have equal length and are aligned by index, e.g. phoneNumbers[i] is
+// sent signNames[i] rendered with templateParams[i]. All recipients
+// share the same templateCode.
+// params: optional parameters for sending SMS. In most case, no need to pass params.
+// You may also specify params by helper functions. e.g. Timestamp(), SignatureNonce().
+//
+// It returns success status, response and error.
+//
+// For example:
+//
+// c := message.NewClient(accessKeyID, accessKeySecret)
+//
+// ok, resp, err := c.SendBatchSMS(
+//	context.Background(),
+//	[]string{"13800138000", "13800138001"},
+//	[]string{"my_product", "my_product"},
+//	"SMS_0000",
+//	[]map[string]string{{"code": "1234"}, {"code": "5678"}},
+// )
+func (c *Client) SendBatchSMS(ctx context.Context, phoneNumbers, signNames []string, templateCode string, templateParams []map[string]string, params ...Param) (bool, *BatchSMSResponse, error) {
+	if len(phoneNumbers) != len(signNames) || len(phoneNumbers) != len(templateParams) {
+		return false, nil, fmt.Errorf("message: phoneNumbers, signNames and templateParams must have equal length, got %d, %d, %d", len(phoneNumbers), len(signNames), len(templateParams))
 	}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	phoneNumberJSON, err := json.Marshal(phoneNumbers)
 	if err != nil {
 		return false, nil, err
 	}
 
-	resp, err := c.Do(req)
+	signNameJSON, err := json.Marshal(signNames)
 	if err != nil {
 		return false, nil, err
 	}
-	defer resp.Body.Close()
 
-	buf, err := ioutil.ReadAll(resp.Body)
+	templateParamJSON, err := json.Marshal(templateParams)
+	if err != nil {
+		return false, nil, err
+	}
+
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for sending batch SMS.
+	v.Set("Action", "SendBatchSms")
+	v.Set("Version", "2017-05-25")
+	v.Set("RegionId", "cn-hangzhou")
+
+	// Set required business parameters
+	v.Set("PhoneNumberJson", string(phoneNumberJSON))
+	v.Set("SignNameJson", string(signNameJSON))
+	v.Set("TemplateCode", templateCode)
+	v.Set("TemplateParamJson", string(templateParamJSON))
+
+	// Override parameters if need.
+	for _, param := range params {
+		param.f(v)
+	}
+
+	buf, err := c.doRequest(ctx, "GET", "sms", "dysmsapi.aliyuncs.com", v)
 	if err != nil {
 		return false, nil, err
 	}
 
 	// Parse JSON response
-	response := &SMSResponse{}
+	response := &BatchSMSResponse{}
 	if err = json.Unmarshal(buf, response); err != nil {
 		return false, nil, err
 	}
@@ -175,7 +350,6 @@ func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, template
 		return false, response, nil
 	}
 	return true, response, nil
-
 }
 
 // MakeSingleCallByTTS makes the single call by TTS.
@@ -193,8 +367,11 @@ func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, template
 //
 // c := message.NewClient(accessKeyID, accessKeySecret)
 //
-// ok, resp, err := c.MakeSingleCallByTTS("02560000000", "1500000000", "TTS_0000", `{"code":"1234","product":"ytx"}`)
-func (c *Client) MakeSingleCallByTTS(calledShowNumber, calledNumber, ttsCode, ttsParam string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
+// ctx governs the request's lifetime, including waits between retries
+// when the client was created with WithRetry.
+//
+// ok, resp, err := c.MakeSingleCallByTTS(context.Background(), "02560000000", "1500000000", "TTS_0000", `{"code":"1234","product":"ytx"}`)
+func (c *Client) MakeSingleCallByTTS(ctx context.Context, calledShowNumber, calledNumber, ttsCode, ttsParam string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
 	v := url.Values{}
 	// Set default common parameters for aliyun services.
 	c.SetDefaultCommonParams(v)
@@ -215,47 +392,154 @@ func (c *Client) MakeSingleCallByTTS(calledShowNumber, calledNumber, ttsCode, tt
 		param.f(v)
 	}
 
-	// Get sorted query string by keys.
-	sortedQueryStr := v.Encode()
-
-	// Get signature.
-	sign := c.SignedString("GET", sortedQueryStr)
-
-	// Make final query string with signature.
-	rawQuery := fmt.Sprintf("Signature=%s&%s", sign, sortedQueryStr)
-
-	// New a URL with host, raw query.
-	u := &url.URL{
-		Scheme:   "http",
-		Host:     "dyvmsapi.aliyuncs.com",
-		Path:     "/",
-		RawQuery: rawQuery,
-	}
-
-	req, err := http.NewRequest("GET", u.String(), nil)
+	buf, err := c.doRequest(ctx, "GET", "voice", "dyvmsapi.aliyuncs.com", v)
 	if err != nil {
 		return false, nil, err
 	}
 
-	resp, err := c.Do(req)
-	if err != nil {
+	// Parse JSON response
+	response := &SingleCallByTTSResponse{}
+	if err = json.Unmarshal(buf, response); err != nil {
 		return false, nil, err
 	}
-	defer resp.Body.Close()
 
-	buf, err := ioutil.ReadAll(resp.Body)
+	if strings.ToUpper(response.Code) != "OK" {
+		return false, response, nil
+	}
+	return true, response, nil
+}
+
+// SmsSendDetailDTO is one entry of QuerySendDetailsResponse's nested
+// SmsSendDetailDTOs.SmsSendDetailDTO array: the delivery detail of a
+// single SMS sent to a single phone number.
+type SmsSendDetailDTO struct {
+	// PhoneNum is the phone number the SMS was sent to.
+	PhoneNum string `json:"PhoneNum"`
+	// Content is the actual content delivered.
+	Content string `json:"Content"`
+	// SendStatus is the delivery status: 1 waiting, 2 failed, 3 delivered.
+	SendStatus int `json:"SendStatus"`
+	// ErrCode is the carrier's error code, e.g. "DELIVRD" on success.
+	ErrCode string `json:"ErrCode"`
+	// TemplateCode is the template used to send the SMS.
+	TemplateCode string `json:"TemplateCode"`
+	// SendDate is the time the SMS was sent, e.g. "2018-01-01 00:00:00".
+	SendDate string `json:"SendDate"`
+	// ReceiveDate is the time aliyun received the delivery report.
+	ReceiveDate string `json:"ReceiveDate"`
+	// OutID is the extended code the caller passed in when sending.
+	OutID string `json:"OutId"`
+}
+
+// SendStatus values. SendStatusFailed and SendStatusDelivered are terminal.
+const (
+	SendStatusWaiting   = 1
+	SendStatusFailed    = 2
+	SendStatusDelivered = 3
+)
+
+// QuerySendDetailsResponse is the response of HTTP request of querying SMS send details.
+type QuerySendDetailsResponse struct {
+	Response
+	TotalCount int `json:"TotalCount"`
+	SmsSendDetailDTOs struct {
+		SmsSendDetailDTO []SmsSendDetailDTO `json:"SmsSendDetailDTO"`
+	} `json:"SmsSendDetailDTOs"`
+}
+
+// QuerySendDetails queries the delivery status of SMS sent on sendDate.
+//
+// phoneNumber: the phone number the SMS was sent to.
+// sendDate: the date the SMS was sent on, in "yyyyMMdd" format.
+// pageSize, currentPage: aliyun paginates results; pageSize must be between 1 and 50.
+// bizID: the BizID returned by SendSMS. Optional; pass "" to query all sends on sendDate.
+//
+// It returns the response and error.
+//
+// For example:
+//
+// c := message.NewClient(accessKeyID, accessKeySecret)
+//
+// ctx governs the request's lifetime, including waits between retries
+// when the client was created with WithRetry.
+//
+// resp, err := c.QuerySendDetails(context.Background(), "13800138000", "20180101", 10, 1, "134523^4351232")
+func (c *Client) QuerySendDetails(ctx context.Context, phoneNumber, sendDate string, pageSize, currentPage int, bizID string) (*QuerySendDetailsResponse, error) {
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for querying SMS send details.
+	v.Set("Action", "QuerySendDetails")
+	v.Set("Version", "2017-05-25")
+	v.Set("RegionId", "cn-hangzhou")
+
+	// Set required business parameters
+	v.Set("PhoneNumber", phoneNumber)
+	v.Set("SendDate", sendDate)
+	v.Set("PageSize", strconv.Itoa(pageSize))
+	v.Set("CurrentPage", strconv.Itoa(currentPage))
+	if bizID != "" {
+		v.Set("BizId", bizID)
+	}
+
+	buf, err := c.doRequest(ctx, "GET", "sms", "dysmsapi.aliyuncs.com", v)
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
 
 	// Parse JSON response
-	response := &SingleCallByTTSResponse{}
+	response := &QuerySendDetailsResponse{}
 	if err = json.Unmarshal(buf, response); err != nil {
-		return false, nil, err
+		return nil, err
 	}
 
-	if strings.ToUpper(response.Code) != "OK" {
-		return false, response, nil
+	return response, nil
+}
+
+// WaitForDelivery polls QuerySendDetails every poll interval until the SMS
+// identified by bizID and phone reaches a terminal status (delivered or
+// failed) or ctx is canceled, and returns the final detail.
+//
+// sendDate is the date the SMS was sent on, in "yyyyMMdd" format; pass it
+// explicitly when polling for a message sent on a previous day. If empty,
+// it defaults to today in Asia/Shanghai.
+func (c *Client) WaitForDelivery(ctx context.Context, bizID, phone, sendDate string, poll time.Duration) (*SmsSendDetailDTO, error) {
+	if sendDate == "" {
+		// QuerySendDetails' SendDate is the Chinese business day the SMS was
+		// recorded against, not the UTC date: derive it from Asia/Shanghai
+		// rather than GenTimestamp, which works in UTC.
+		sendDate = time.Now().In(shanghai()).Format("20060102")
 	}
-	return true, response, nil
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		resp, err := c.QuerySendDetails(ctx, phone, sendDate, 1, 1, bizID)
+		if err != nil {
+			return nil, err
+		}
+		if strings.ToUpper(resp.Code) == "OK" && len(resp.SmsSendDetailDTOs.SmsSendDetailDTO) > 0 {
+			detail := resp.SmsSendDetailDTOs.SmsSendDetailDTO[0]
+			if detail.SendStatus == SendStatusDelivered || detail.SendStatus == SendStatusFailed {
+				return &detail, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// shanghai returns the Asia/Shanghai location, falling back to a fixed
+// UTC+8 offset if the system has no tzdata installed.
+func shanghai() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Shanghai"); err == nil {
+		return loc
+	}
+	return time.FixedZone("CST", 8*60*60)
 }
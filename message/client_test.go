@@ -0,0 +1,45 @@
+package message
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSendBatchSMSLengthMismatch(t *testing.T) {
+	c := NewClient("id", "secret")
+
+	tests := []struct {
+		name           string
+		phoneNumbers   []string
+		signNames      []string
+		templateParams []map[string]string
+	}{
+		{
+			name:           "signNames shorter",
+			phoneNumbers:   []string{"13800138000", "13800138001"},
+			signNames:      []string{"a"},
+			templateParams: []map[string]string{{"code": "1"}, {"code": "2"}},
+		},
+		{
+			name:           "templateParams shorter",
+			phoneNumbers:   []string{"13800138000", "13800138001"},
+			signNames:      []string{"a", "b"},
+			templateParams: []map[string]string{{"code": "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, resp, err := c.SendBatchSMS(context.Background(), tt.phoneNumbers, tt.signNames, "SMS_0000", tt.templateParams)
+			if err == nil {
+				t.Fatal("expected an error for mismatched slice lengths, got nil")
+			}
+			if ok {
+				t.Error("expected ok to be false")
+			}
+			if resp != nil {
+				t.Error("expected a nil response")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Package aliyun adapts the existing message.Client into a gateway.Gateway
+// so aliyun can be used as one provider among several behind a
+// gateway.Dispatcher.
+package aliyun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/gateway"
+)
+
+// Gateway sends SMS through aliyun's dysmsapi.
+type Gateway struct {
+	client *message.Client
+}
+
+// New creates a Gateway backed by client.
+func New(client *message.Client) *Gateway {
+	return &Gateway{client: client}
+}
+
+// Name returns "aliyun".
+func (g *Gateway) Name() string {
+	return "aliyun"
+}
+
+// Send renders tpl's Params to JSON and sends it via Client.SendSMS.
+func (g *Gateway) Send(ctx context.Context, to []string, tpl gateway.Template) (*gateway.Response, error) {
+	param, err := json.Marshal(tpl.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, resp, err := g.client.SendSMS(ctx, to, tpl.SignName, tpl.TemplateCode, string(param))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("aliyun: send failed: %s: %s", resp.Code, resp.Message)
+	}
+
+	return &gateway.Response{
+		RequestID: resp.RequestID,
+		BizID:     resp.BizID,
+		Provider:  g.Name(),
+		Raw:       resp,
+	}, nil
+}
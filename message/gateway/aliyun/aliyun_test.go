@@ -0,0 +1,74 @@
+package aliyun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/gateway"
+)
+
+// roundTripFunc lets a test stub http.Client's transport without making
+// any real network call.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func jsonResponse(v interface{}) *http.Response {
+	buf, _ := json.Marshal(v)
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader(buf)),
+	}
+}
+
+func TestSendReturnsNormalizedResponse(t *testing.T) {
+	client := message.NewClient("id", "secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(message.SMSResponse{
+			Response: message.Response{Code: "OK", Message: "OK", RequestID: "req-1"},
+			BizID:    "biz-1",
+		}), nil
+	})
+
+	g := New(client)
+
+	resp, err := g.Send(context.Background(), []string{"13800138000"}, gateway.Template{
+		SignName:     "Acme",
+		TemplateCode: "SMS_0000",
+		Params:       map[string]string{"code": "1234"},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.RequestID != "req-1" || resp.BizID != "biz-1" || resp.Provider != "aliyun" {
+		t.Errorf("Send() = %+v, unexpected fields", resp)
+	}
+}
+
+func TestSendFailureCode(t *testing.T) {
+	client := message.NewClient("id", "secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(message.SMSResponse{
+			Response: message.Response{Code: "isv.BUSINESS_LIMIT_CONTROL", Message: "rate limited"},
+		}), nil
+	})
+
+	g := New(client)
+
+	_, err := g.Send(context.Background(), []string{"13800138000"}, gateway.Template{SignName: "Acme", TemplateCode: "SMS_0000"})
+	if err == nil {
+		t.Fatal("Send() error = nil, want error for a non-OK response code")
+	}
+}
+
+func TestName(t *testing.T) {
+	g := New(message.NewClient("id", "secret"))
+	if g.Name() != "aliyun" {
+		t.Errorf("Name() = %q, want %q", g.Name(), "aliyun")
+	}
+}
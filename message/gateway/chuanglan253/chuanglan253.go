@@ -0,0 +1,105 @@
+// Package chuanglan253 implements a gateway.Gateway for Chuanglan 253's
+// SMS API, a simple JSON POST authenticated by account and password
+// rather than POP-style request signing.
+package chuanglan253
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/northbright/aliyun/message/gateway"
+)
+
+const sendURL = "https://smssh1.253.com/msg/send/json"
+
+// Gateway sends SMS through Chuanglan 253.
+type Gateway struct {
+	http.Client
+	account  string
+	password string
+}
+
+// New creates a Gateway. account and password are issued by Chuanglan
+// when the SMS product is provisioned.
+func New(account, password string) *Gateway {
+	return &Gateway{account: account, password: password}
+}
+
+// Name returns "chuanglan253".
+func (g *Gateway) Name() string {
+	return "chuanglan253"
+}
+
+type sendRequest struct {
+	Account  string `json:"account"`
+	Password string `json:"password"`
+	Msg      string `json:"msg"`
+	Phone    string `json:"phone"`
+	Report   string `json:"report"`
+}
+
+type sendResponse struct {
+	Code   string `json:"code"`
+	ErrMsg string `json:"errorMsg"`
+	MsgID  string `json:"msgId"`
+	Time   string `json:"time"`
+}
+
+// Send posts tpl.Body, prefixed with tpl.SignName, to the recipients in
+// to as a comma-separated list. Chuanglan 253 has no server-side
+// template concept: the caller must pre-render tpl.Body into its final
+// text (TemplateCode and Params are not used here).
+func (g *Gateway) Send(ctx context.Context, to []string, tpl gateway.Template) (*gateway.Response, error) {
+	if tpl.Body == "" {
+		return nil, fmt.Errorf("chuanglan253: tpl.Body is required, this gateway has no server-side template")
+	}
+	msg := fmt.Sprintf("【%s】%s", tpl.SignName, tpl.Body)
+
+	body, err := json.Marshal(sendRequest{
+		Account:  g.account,
+		Password: g.password,
+		Msg:      msg,
+		Phone:    strings.Join(to, ","),
+		Report:   "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sendURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out sendResponse
+	if err := json.Unmarshal(buf, &out); err != nil {
+		return nil, err
+	}
+	if out.Code != "0" {
+		return nil, fmt.Errorf("chuanglan253: send failed: %s: %s", out.Code, out.ErrMsg)
+	}
+
+	return &gateway.Response{
+		RequestID: out.MsgID,
+		BizID:     out.MsgID,
+		Provider:  g.Name(),
+		Raw:       out,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package chuanglan253
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/northbright/aliyun/message/gateway"
+)
+
+// roundTripFunc lets a test stub http.Client's transport without making
+// any real network call.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestSendRequiresBody(t *testing.T) {
+	g := New("account", "password")
+
+	_, err := g.Send(context.Background(), []string{"13800138000"}, gateway.Template{SignName: "Acme"})
+	if err == nil {
+		t.Error("Send() error = nil, want error when tpl.Body is empty")
+	}
+}
+
+func TestSendPrefixesSignNameAndJoinsRecipients(t *testing.T) {
+	g := New("myaccount", "mypassword")
+
+	var gotReq sendRequest
+	g.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		buf, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("reading request body: %v", err)
+		}
+		if err := json.Unmarshal(buf, &gotReq); err != nil {
+			t.Fatalf("unmarshaling request body: %v", err)
+		}
+
+		body, _ := json.Marshal(sendResponse{Code: "0", MsgID: "msg-1"})
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	resp, err := g.Send(context.Background(), []string{"13800138000", "13800138001"}, gateway.Template{SignName: "Acme", Body: "your code is 1234"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if !strings.Contains(gotReq.Msg, "【Acme】") || !strings.Contains(gotReq.Msg, "your code is 1234") {
+		t.Errorf("Msg = %q, want it to contain the bracketed sign name and tpl.Body", gotReq.Msg)
+	}
+	if gotReq.Phone != "13800138000,13800138001" {
+		t.Errorf("Phone = %q, want recipients joined by commas", gotReq.Phone)
+	}
+	if gotReq.Account != "myaccount" || gotReq.Password != "mypassword" {
+		t.Errorf("Account/Password = %q/%q, want %q/%q", gotReq.Account, gotReq.Password, "myaccount", "mypassword")
+	}
+	if resp.RequestID != "msg-1" || resp.BizID != "msg-1" || resp.Provider != "chuanglan253" {
+		t.Errorf("Response = %+v, unexpected fields", resp)
+	}
+}
+
+func TestSendFailureCode(t *testing.T) {
+	g := New("account", "password")
+
+	g.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := json.Marshal(sendResponse{Code: "124", ErrMsg: "account not exist"})
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	_, err := g.Send(context.Background(), []string{"13800138000"}, gateway.Template{SignName: "Acme", Body: "hi"})
+	if err == nil {
+		t.Fatal("Send() error = nil, want error for a non-zero response code")
+	}
+	if !strings.Contains(err.Error(), "account not exist") {
+		t.Errorf("Send() error = %v, want it to mention the carrier's ErrMsg", err)
+	}
+}
@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Weighted pairs a Gateway with its priority: a Dispatcher tries gateways
+// in descending Weight order and fails over to the next one on error, so
+// e.g. an aliyun primary with Tencent and Chuanglan 253 as fallbacks can
+// be configured without touching call sites.
+type Weighted struct {
+	Gateway Gateway
+	Weight  int
+}
+
+// Dispatcher sends through a prioritized list of gateways, falling over
+// to the next one whenever the current one returns an error.
+type Dispatcher struct {
+	gateways []Weighted
+}
+
+// NewDispatcher creates a Dispatcher from the given weighted gateways.
+// Higher Weight is tried first; ties keep the order passed in.
+func NewDispatcher(gateways ...Weighted) *Dispatcher {
+	ordered := make([]Weighted, len(gateways))
+	copy(ordered, gateways)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Weight > ordered[j].Weight
+	})
+	return &Dispatcher{gateways: ordered}
+}
+
+// Send tries each gateway in priority order and returns the first
+// successful Response. If every gateway fails, it returns an error that
+// wraps the last gateway's error.
+func (d *Dispatcher) Send(ctx context.Context, to []string, tpl Template) (*Response, error) {
+	if len(d.gateways) == 0 {
+		return nil, fmt.Errorf("gateway: dispatcher has no gateways configured")
+	}
+
+	var lastErr error
+	for _, w := range d.gateways {
+		resp, err := w.Gateway.Send(ctx, to, tpl)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = fmt.Errorf("gateway %s: %w", w.Gateway.Name(), err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+	}
+	return nil, fmt.Errorf("gateway: all gateways failed, last error: %w", lastErr)
+}
@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeGateway struct {
+	name string
+	resp *Response
+	err  error
+	// calls records how many times Send was invoked, so tests can assert
+	// on failover behavior.
+	calls int
+}
+
+func (g *fakeGateway) Send(ctx context.Context, to []string, tpl Template) (*Response, error) {
+	g.calls++
+	return g.resp, g.err
+}
+
+func (g *fakeGateway) Name() string { return g.name }
+
+func TestDispatcherSendNoGateways(t *testing.T) {
+	d := NewDispatcher()
+
+	if _, err := d.Send(context.Background(), []string{"13800138000"}, Template{}); err == nil {
+		t.Error("Send() error = nil, want error for a dispatcher with no gateways")
+	}
+}
+
+func TestDispatcherSendUsesHighestWeightFirst(t *testing.T) {
+	primary := &fakeGateway{name: "primary", resp: &Response{Provider: "primary"}}
+	fallback := &fakeGateway{name: "fallback", resp: &Response{Provider: "fallback"}}
+
+	d := NewDispatcher(
+		Weighted{Gateway: fallback, Weight: 1},
+		Weighted{Gateway: primary, Weight: 10},
+	)
+
+	resp, err := d.Send(context.Background(), []string{"13800138000"}, Template{})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.Provider != "primary" {
+		t.Errorf("Send() used provider %q, want %q", resp.Provider, "primary")
+	}
+	if fallback.calls != 0 {
+		t.Errorf("fallback.calls = %d, want 0 since primary succeeded", fallback.calls)
+	}
+}
+
+func TestDispatcherSendFailsOverOnError(t *testing.T) {
+	primary := &fakeGateway{name: "primary", err: errors.New("primary down")}
+	fallback := &fakeGateway{name: "fallback", resp: &Response{Provider: "fallback"}}
+
+	d := NewDispatcher(
+		Weighted{Gateway: primary, Weight: 10},
+		Weighted{Gateway: fallback, Weight: 1},
+	)
+
+	resp, err := d.Send(context.Background(), []string{"13800138000"}, Template{})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.Provider != "fallback" {
+		t.Errorf("Send() used provider %q, want %q", resp.Provider, "fallback")
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary.calls = %d, want 1", primary.calls)
+	}
+}
+
+func TestDispatcherSendAllFail(t *testing.T) {
+	primary := &fakeGateway{name: "primary", err: errors.New("primary down")}
+	fallback := &fakeGateway{name: "fallback", err: errors.New("fallback down")}
+
+	d := NewDispatcher(
+		Weighted{Gateway: primary, Weight: 10},
+		Weighted{Gateway: fallback, Weight: 1},
+	)
+
+	_, err := d.Send(context.Background(), []string{"13800138000"}, Template{})
+	if err == nil {
+		t.Fatal("Send() error = nil, want an error wrapping the last gateway's failure")
+	}
+	if !errors.Is(err, fallback.err) {
+		t.Errorf("Send() error = %v, want it to wrap %v", err, fallback.err)
+	}
+}
+
+func TestDispatcherSendTiesKeepPassedOrder(t *testing.T) {
+	first := &fakeGateway{name: "first", resp: &Response{Provider: "first"}}
+	second := &fakeGateway{name: "second", resp: &Response{Provider: "second"}}
+
+	d := NewDispatcher(
+		Weighted{Gateway: first, Weight: 1},
+		Weighted{Gateway: second, Weight: 1},
+	)
+
+	resp, err := d.Send(context.Background(), []string{"13800138000"}, Template{})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.Provider != "first" {
+		t.Errorf("Send() used provider %q, want %q (first passed in, equal weight)", resp.Provider, "first")
+	}
+}
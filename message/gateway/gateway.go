@@ -0,0 +1,55 @@
+// Package gateway defines a provider-agnostic abstraction for sending SMS
+// through one or more upstream carriers (aliyun, Tencent Cloud, Chuanglan
+// 253...), so call sites do not need to know which carrier is actually
+// delivering a message.
+package gateway
+
+import (
+	"context"
+)
+
+// Template is the content to render and send. SignName and TemplateCode
+// are carrier-specific identifiers applied for in each provider's control
+// panel; Params renders into the template's placeholders, e.g.
+// {"code": "1234"}.
+type Template struct {
+	SignName     string
+	TemplateCode string
+	Params       map[string]string
+	// ParamOrder lists Params' keys in the order a provider that binds
+	// parameters positionally (e.g. Tencent) must send them, matching
+	// the placeholder order in TemplateCode. Required whenever Params
+	// has more than one entry and such a provider is used; map iteration
+	// order is randomized and cannot be relied on to get this right.
+	ParamOrder []string
+	// Body is a fully pre-rendered message, for providers with no
+	// server-side template concept (e.g. Chuanglan 253), which send
+	// exactly this text instead of TemplateCode/Params.
+	Body string
+}
+
+// Response is the normalized result of a Send call. RequestID and BizID
+// are the provider's own identifiers for the call and the individual
+// message, mapped onto aliyun's naming since it is the most commonly
+// queried shape; Raw holds the provider's original, unmodified payload
+// for callers that need carrier-specific detail.
+type Response struct {
+	// RequestID identifies this API call.
+	RequestID string
+	// BizID identifies the sent message and can be used to query its
+	// delivery status. Not all providers return one.
+	BizID string
+	// Provider is the Name() of the gateway that produced this response.
+	Provider string
+	// Raw is the provider's undecoded response.
+	Raw interface{}
+}
+
+// Gateway is implemented by each upstream SMS provider.
+type Gateway interface {
+	// Send delivers tpl to the given recipients and returns the
+	// provider's normalized response.
+	Send(ctx context.Context, to []string, tpl Template) (*Response, error)
+	// Name identifies the gateway, e.g. "aliyun", "tencent", "chuanglan253".
+	Name() string
+}
@@ -0,0 +1,134 @@
+package tencent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message/gateway"
+)
+
+func TestOrderedParamsEmpty(t *testing.T) {
+	params, err := orderedParams(gateway.Template{})
+	if err != nil {
+		t.Fatalf("orderedParams() error = %v", err)
+	}
+	if params != nil {
+		t.Errorf("orderedParams() = %v, want nil", params)
+	}
+}
+
+func TestOrderedParamsSingleNeedsNoOrder(t *testing.T) {
+	params, err := orderedParams(gateway.Template{Params: map[string]string{"code": "1234"}})
+	if err != nil {
+		t.Fatalf("orderedParams() error = %v", err)
+	}
+	if len(params) != 1 || params[0] != "1234" {
+		t.Errorf("orderedParams() = %v, want [1234]", params)
+	}
+}
+
+func TestOrderedParamsFollowsParamOrder(t *testing.T) {
+	tpl := gateway.Template{
+		Params:     map[string]string{"code": "1234", "product": "Acme"},
+		ParamOrder: []string{"product", "code"},
+	}
+
+	params, err := orderedParams(tpl)
+	if err != nil {
+		t.Fatalf("orderedParams() error = %v", err)
+	}
+	want := []string{"Acme", "1234"}
+	if len(params) != len(want) || params[0] != want[0] || params[1] != want[1] {
+		t.Errorf("orderedParams() = %v, want %v", params, want)
+	}
+}
+
+func TestOrderedParamsMissingParamOrder(t *testing.T) {
+	tpl := gateway.Template{Params: map[string]string{"code": "1234", "product": "Acme"}}
+
+	if _, err := orderedParams(tpl); err == nil {
+		t.Error("orderedParams() error = nil, want error for multi-entry Params with no ParamOrder")
+	}
+}
+
+func TestOrderedParamsUnknownKey(t *testing.T) {
+	tpl := gateway.Template{
+		Params:     map[string]string{"code": "1234", "product": "Acme"},
+		ParamOrder: []string{"code", "missing"},
+	}
+
+	if _, err := orderedParams(tpl); err == nil {
+		t.Error("orderedParams() error = nil, want error for ParamOrder referencing an unknown key")
+	}
+}
+
+// wantAuthorization independently recomputes the TC3-HMAC-SHA256
+// Authorization header for the given inputs, so it catches a
+// transposition bug in authorize's canonical-request or string-to-sign
+// construction rather than just mirroring it.
+func wantAuthorization(secretID, secretKey string, now time.Time, body []byte) string {
+	date := now.Format("2006-01-02")
+	bodyHash := sha256.Sum256(body)
+
+	canonicalRequest := "POST\n/\n\ncontent-type:application/json\nhost:" + host + "\n\ncontent-type;host\n" +
+		hex.EncodeToString(bodyHash[:])
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+
+	credentialScope := fmt.Sprintf("%s/%s/tc3_request", date, service)
+	stringToSign := fmt.Sprintf("TC3-HMAC-SHA256\n%d\n%s\n%s", now.Unix(), credentialScope, hex.EncodeToString(crHash[:]))
+
+	mac := func(key []byte, msg string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(msg))
+		return h.Sum(nil)
+	}
+	kDate := mac([]byte("TC3"+secretKey), date)
+	kService := mac(kDate, service)
+	kSigning := mac(kService, "tc3_request")
+	signature := hex.EncodeToString(mac(kSigning, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		secretID, credentialScope, signature)
+}
+
+func TestGatewayAuthorizeMatchesIndependentComputation(t *testing.T) {
+	g := New("AKIDexamplesecretid", "examplesecretkey", "1400000000", "ap-guangzhou")
+	now := time.Date(2021, time.January, 11, 3, 4, 5, 0, time.UTC)
+	body := []byte(`{"PhoneNumberSet":["13800138000"]}`)
+
+	authorization, timestamp := g.authorize(now, body)
+
+	if timestamp != now.Unix() {
+		t.Errorf("authorize() timestamp = %d, want %d", timestamp, now.Unix())
+	}
+	if want := wantAuthorization(g.secretID, g.secretKey, now, body); authorization != want {
+		t.Errorf("authorize() authorization =\n%s\nwant\n%s", authorization, want)
+	}
+}
+
+func TestGatewayAuthorizeVariesWithBody(t *testing.T) {
+	g := New("AKIDexamplesecretid", "examplesecretkey", "1400000000", "ap-guangzhou")
+	now := time.Date(2021, time.January, 11, 3, 4, 5, 0, time.UTC)
+
+	a1, _ := g.authorize(now, []byte(`{"a":1}`))
+	a2, _ := g.authorize(now, []byte(`{"a":2}`))
+	if a1 == a2 {
+		t.Error("authorize() produced the same Authorization header for two different bodies")
+	}
+}
+
+func TestGatewayAuthorizeIncludesCredentialScope(t *testing.T) {
+	g := New("AKIDexamplesecretid", "examplesecretkey", "1400000000", "ap-guangzhou")
+	now := time.Date(2021, time.January, 11, 3, 4, 5, 0, time.UTC)
+
+	authorization, _ := g.authorize(now, []byte(`{}`))
+	wantScope := "2021-01-11/sms/tc3_request"
+	if !strings.Contains(authorization, wantScope) {
+		t.Errorf("authorize() authorization = %q, want it to contain credential scope %q", authorization, wantScope)
+	}
+}
@@ -0,0 +1,105 @@
+package message
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ClientOption configures a Client. Pass one or more to NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPS makes the client use https:// instead of the default
+// http:// for all requests.
+func WithHTTPS() ClientOption {
+	return func(c *Client) {
+		c.useHTTPS = true
+	}
+}
+
+// WithRetry sets the RetryPolicy applied to SendSMS, SendBatchSMS,
+// MakeSingleCallByTTS and QuerySendDetails. Without this option, requests
+// are attempted once and any error or non-OK response is returned as-is.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithRateLimit throttles outgoing requests to at most qps per second,
+// allowing short bursts of up to burst requests. aliyun enforces its own
+// per-signature QPS limits and returns isv.BUSINESS_LIMIT_CONTROL when
+// they are exceeded; this lets a caller stay under that limit proactively.
+func WithRateLimit(qps int, burst int) ClientOption {
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+}
+
+// WithEndpoint overrides the host used for a given service, e.g.
+// WithEndpoint("sms", "dysmsapi.ap-southeast-1.aliyuncs.com") to target
+// a region other than the cn-hangzhou default.
+//
+// service is "sms" for SendSMS/SendBatchSMS/QuerySendDetails or "voice"
+// for MakeSingleCallByTTS.
+func WithEndpoint(service, host string) ClientOption {
+	return func(c *Client) {
+		if c.endpoints == nil {
+			c.endpoints = make(map[string]string)
+		}
+		c.endpoints[service] = host
+	}
+}
+
+// RetryPolicy controls how a Client retries a transient request failure.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values less than 1 are treated as 1 (no retry).
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; each subsequent
+	// retry doubles it, up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay.
+	MaxDelay time.Duration
+	// RetryableCodes lists aliyun business error Codes that should be
+	// retried, e.g. "isv.BUSINESS_LIMIT_CONTROL". HTTP 5xx responses are
+	// always retried regardless of this list.
+	RetryableCodes []string
+}
+
+// DefaultRetryPolicy makes up to 3 attempts with exponential backoff
+// between 200ms and 2s, retrying aliyun's throttling error code.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	BaseDelay:      200 * time.Millisecond,
+	MaxDelay:       2 * time.Second,
+	RetryableCodes: []string{"isv.BUSINESS_LIMIT_CONTROL", "Throttling"},
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) isRetryableCode(code string) bool {
+	for _, c := range p.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the backoff delay before retry attempt n (0-indexed,
+// n=0 is the delay before the second attempt), with up to 20% jitter.
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << uint(n)
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5 + 1))
+	return d + jitter
+}
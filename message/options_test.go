@@ -0,0 +1,78 @@
+package message
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyIsRetryableCode(t *testing.T) {
+	policy := RetryPolicy{RetryableCodes: []string{"isv.BUSINESS_LIMIT_CONTROL", "Throttling"}}
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"isv.BUSINESS_LIMIT_CONTROL", true},
+		{"Throttling", true},
+		{"OK", false},
+		{"InvalidParameter", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := policy.isRetryableCode(tt.code); got != tt.want {
+			t.Errorf("isRetryableCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{0, 1},
+		{-1, 1},
+		{1, 1},
+		{3, 3},
+	}
+
+	for _, tt := range tests {
+		p := RetryPolicy{MaxAttempts: tt.configured}
+		if got := p.maxAttempts(); got != tt.want {
+			t.Errorf("RetryPolicy{MaxAttempts: %d}.maxAttempts() = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyDelayCapsAtMaxDelay(t *testing.T) {
+	p := RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  300 * time.Millisecond,
+	}
+
+	// With doubling and no cap, attempt 5 would be 3.2s; MaxDelay plus up
+	// to 20% jitter bounds it well below that.
+	d := p.delay(5)
+	if d < p.MaxDelay {
+		t.Errorf("delay(5) = %v, want at least MaxDelay (%v)", d, p.MaxDelay)
+	}
+	if max := p.MaxDelay + p.MaxDelay/5 + 1; d > max {
+		t.Errorf("delay(5) = %v, want at most %v", d, max)
+	}
+}
+
+func TestRetryPolicyDelayGrowsExponentially(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond}
+
+	// Without jitter this would be exactly 100ms/200ms; allow for the
+	// up-to-20% jitter delay() adds on top.
+	d0 := p.delay(0)
+	d1 := p.delay(1)
+	if d0 < p.BaseDelay || d0 > p.BaseDelay+p.BaseDelay/5+1 {
+		t.Errorf("delay(0) = %v, want within jitter range of %v", d0, p.BaseDelay)
+	}
+	if d1 < 2*p.BaseDelay {
+		t.Errorf("delay(1) = %v, want at least double delay(0)'s base of %v", d1, p.BaseDelay)
+	}
+}
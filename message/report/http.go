@@ -0,0 +1,76 @@
+package report
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+// ackBody is the body aliyun expects in response to a pushed report,
+// regardless of how many elements it contained.
+const ackBody = `{"code":0,"msg":"None"}`
+
+// NewHTTPReceiver returns an http.Handler for the URL configured in
+// aliyun's console as the HTTP callback for SmsReport, SmsUp and
+// VoiceReport push. It decodes the JSON array body, dispatches each
+// element to handler by type, and acks with ackBody. If the body cannot
+// be read or any element fails to decode, it responds 400 Bad Request
+// with the error instead of ackBody.
+func NewHTTPReceiver(handler ReportHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err := dispatch(buf, handler); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(ackBody))
+	})
+}
+
+// dispatch decodes buf as a JSON array of reports and calls handler for
+// each element, sniffing its concrete type from its fields since aliyun
+// pushes SmsReport, SmsUp and VoiceReport elements in the same array
+// without an explicit discriminator.
+func dispatch(buf []byte, handler ReportHandler) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(buf, &elems); err != nil {
+		return err
+	}
+
+	for _, elem := range elems {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(elem, &fields); err != nil {
+			return err
+		}
+
+		switch {
+		case fields["call_id"] != nil:
+			var v VoiceReport
+			if err := json.Unmarshal(elem, &v); err != nil {
+				return err
+			}
+			handler.OnVoiceReport(v)
+		case fields["report_time"] != nil:
+			var v SMSReport
+			if err := json.Unmarshal(elem, &v); err != nil {
+				return err
+			}
+			handler.OnSMSReport(v)
+		default:
+			var v SMSUp
+			if err := json.Unmarshal(elem, &v); err != nil {
+				return err
+			}
+			handler.OnSMSUp(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package report
+
+import "testing"
+
+type recordingHandler struct {
+	smsReports   []SMSReport
+	smsUps       []SMSUp
+	voiceReports []VoiceReport
+}
+
+func (h *recordingHandler) OnSMSReport(r SMSReport)     { h.smsReports = append(h.smsReports, r) }
+func (h *recordingHandler) OnSMSUp(u SMSUp)             { h.smsUps = append(h.smsUps, u) }
+func (h *recordingHandler) OnVoiceReport(v VoiceReport) { h.voiceReports = append(h.voiceReports, v) }
+
+func TestDispatchSniffsSMSReport(t *testing.T) {
+	body := `[{"phone_number":"13800138000","send_time":"20180101120000","report_time":"20180101120005","success":true,"err_code":"DELIVRD","sms_size":"1","biz_id":"134^456","out_id":""}]`
+
+	h := &recordingHandler{}
+	if err := dispatch([]byte(body), h); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+
+	if len(h.smsReports) != 1 {
+		t.Fatalf("len(smsReports) = %d, want 1", len(h.smsReports))
+	}
+	if h.smsReports[0].BizID != "134^456" || !h.smsReports[0].Success {
+		t.Errorf("smsReports[0] = %+v, unexpected fields", h.smsReports[0])
+	}
+	if len(h.smsUps) != 0 || len(h.voiceReports) != 0 {
+		t.Errorf("dispatch() also called OnSMSUp/OnVoiceReport for an SMSReport element")
+	}
+}
+
+func TestDispatchSniffsSMSUp(t *testing.T) {
+	body := `[{"sign_name":"my_product","phone_number":"13800138000","content":"reply text","send_time":"20180101120000","sequence_id":"1"}]`
+
+	h := &recordingHandler{}
+	if err := dispatch([]byte(body), h); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+
+	if len(h.smsUps) != 1 {
+		t.Fatalf("len(smsUps) = %d, want 1", len(h.smsUps))
+	}
+	if h.smsUps[0].Content != "reply text" {
+		t.Errorf("smsUps[0].Content = %q, want %q", h.smsUps[0].Content, "reply text")
+	}
+	if len(h.smsReports) != 0 || len(h.voiceReports) != 0 {
+		t.Errorf("dispatch() also called OnSMSReport/OnVoiceReport for an SMSUp element")
+	}
+}
+
+func TestDispatchSniffsVoiceReport(t *testing.T) {
+	body := `[{"call_id":"call-1","called_number":"13800138000","called_show_number":"02560000000","status":"success","report_time":"20180101120005"}]`
+
+	h := &recordingHandler{}
+	if err := dispatch([]byte(body), h); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+
+	if len(h.voiceReports) != 1 {
+		t.Fatalf("len(voiceReports) = %d, want 1", len(h.voiceReports))
+	}
+	if h.voiceReports[0].CallID != "call-1" {
+		t.Errorf("voiceReports[0].CallID = %q, want %q", h.voiceReports[0].CallID, "call-1")
+	}
+	// VoiceReport also has a report_time field, so the switch must check
+	// for call_id before report_time or this would be misdispatched as
+	// an SMSReport.
+	if len(h.smsReports) != 0 || len(h.smsUps) != 0 {
+		t.Errorf("dispatch() also called OnSMSReport/OnSMSUp for a VoiceReport element")
+	}
+}
+
+func TestDispatchMixedArray(t *testing.T) {
+	body := `[
+		{"phone_number":"13800138000","report_time":"20180101120005","success":true},
+		{"sign_name":"my_product","phone_number":"13800138001","content":"hi"},
+		{"call_id":"call-1","called_number":"13800138002"}
+	]`
+
+	h := &recordingHandler{}
+	if err := dispatch([]byte(body), h); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+
+	if len(h.smsReports) != 1 || len(h.smsUps) != 1 || len(h.voiceReports) != 1 {
+		t.Errorf("got %d smsReports, %d smsUps, %d voiceReports, want 1 each",
+			len(h.smsReports), len(h.smsUps), len(h.voiceReports))
+	}
+}
+
+func TestDispatchInvalidJSON(t *testing.T) {
+	h := &recordingHandler{}
+	if err := dispatch([]byte("not json"), h); err == nil {
+		t.Error("dispatch() error = nil, want an error for invalid JSON")
+	}
+}
@@ -0,0 +1,62 @@
+// Package report decodes the delivery status reports and upstream MO
+// messages aliyun pushes for SMS and voice calls (SmsReport, SmsUp,
+// VoiceReport), delivered either as an HTTP callback or via an MNS
+// queue, and dispatches them to a ReportHandler.
+package report
+
+// SMSReport is a delivery status report for a single sent SMS.
+type SMSReport struct {
+	// PhoneNumber is the recipient the SMS was sent to.
+	PhoneNumber string `json:"phone_number"`
+	// SendTime is when the SMS was sent, e.g. "20180101120000".
+	SendTime string `json:"send_time"`
+	// ReportTime is when the carrier's delivery report arrived.
+	ReportTime string `json:"report_time"`
+	// Success reports whether the SMS was delivered.
+	Success bool `json:"success"`
+	// ErrCode is the carrier's delivery error code, e.g. "DELIVRD".
+	ErrCode string `json:"err_code"`
+	// SmsSize is the number of SMS segments the message was split into.
+	SmsSize string `json:"sms_size"`
+	// BizID is the BizId returned by SendSMS/SendBatchSMS.
+	BizID string `json:"biz_id"`
+	// OutID is the extended code the caller passed in when sending.
+	OutID string `json:"out_id"`
+}
+
+// SMSUp is an upstream (MO) SMS a user sent in reply to a signature.
+type SMSUp struct {
+	// SignName is the signature the user replied to.
+	SignName string `json:"sign_name"`
+	// PhoneNumber is the phone number the reply was sent from.
+	PhoneNumber string `json:"phone_number"`
+	// Content is the text of the reply.
+	Content string `json:"content"`
+	// SendTime is when the reply was sent.
+	SendTime string `json:"send_time"`
+	// SequenceID uniquely identifies this upstream message.
+	SequenceID string `json:"sequence_id"`
+}
+
+// VoiceReport is a delivery status report for a single TTS call.
+type VoiceReport struct {
+	// CallID is the CallId returned by MakeSingleCallByTTS.
+	CallID string `json:"call_id"`
+	// CalledNumber is the number that was called.
+	CalledNumber string `json:"called_number"`
+	// CalledShowNumber is the caller ID shown to CalledNumber.
+	CalledShowNumber string `json:"called_show_number"`
+	// Status is the carrier's call status, e.g. "calling", "ringing", "success".
+	Status string `json:"status"`
+	// ReportTime is when this status report was generated.
+	ReportTime string `json:"report_time"`
+}
+
+// ReportHandler receives decoded reports. Implementations should return
+// promptly; NewMNSPoller only deletes a queue message after its handler
+// calls return, so a slow handler delays the queue's redelivery timeout.
+type ReportHandler interface {
+	OnSMSReport(SMSReport)
+	OnSMSUp(SMSUp)
+	OnVoiceReport(VoiceReport)
+}
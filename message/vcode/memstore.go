@@ -0,0 +1,108 @@
+package vcode
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is one MemStore record.
+type entry struct {
+	code         string
+	attemptsLeft int
+	expiresAt    time.Time
+}
+
+// MemStore is an in-process Store backed by a map, with a background
+// goroutine sweeping expired entries. It is suitable for a single
+// instance; use RedisStore when codes must be shared across instances.
+type MemStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+
+	stop chan struct{}
+}
+
+// NewMemStore creates a MemStore and starts its expiry sweeper, which
+// runs every sweepInterval until the returned MemStore is closed.
+func NewMemStore(sweepInterval time.Duration) *MemStore {
+	s := &MemStore{
+		entries: make(map[string]entry),
+		stop:    make(chan struct{}),
+	}
+	go s.sweep(sweepInterval)
+	return s
+}
+
+// Close stops the background sweeper.
+func (s *MemStore) Close() {
+	close(s.stop)
+}
+
+func (s *MemStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for phone, e := range s.entries {
+				if now.After(e.expiresAt) {
+					delete(s.entries, phone)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Put implements Store.
+func (s *MemStore) Put(ctx context.Context, phone, code string, attemptsLeft int, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[phone] = entry{
+		code:         code,
+		attemptsLeft: attemptsLeft,
+		expiresAt:    time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// Get implements Store.
+func (s *MemStore) Get(ctx context.Context, phone string) (string, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[phone]
+	if !ok || time.Now().After(e.expiresAt) {
+		return "", 0, ErrNotFound
+	}
+	return e.code, e.attemptsLeft, nil
+}
+
+// DecrementAttempts implements Store.
+func (s *MemStore) DecrementAttempts(ctx context.Context, phone string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[phone]
+	if !ok || time.Now().After(e.expiresAt) {
+		return 0, ErrNotFound
+	}
+	e.attemptsLeft--
+	s.entries[phone] = e
+	return e.attemptsLeft, nil
+}
+
+// Delete implements Store.
+func (s *MemStore) Delete(ctx context.Context, phone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, phone)
+	return nil
+}
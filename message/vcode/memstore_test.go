@@ -0,0 +1,103 @@
+package vcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemStorePutGet(t *testing.T) {
+	s := NewMemStore(time.Hour)
+	defer s.Close()
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "13800138000", "123456", 3, time.Minute); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	code, attemptsLeft, err := s.Get(ctx, "13800138000")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if code != "123456" || attemptsLeft != 3 {
+		t.Errorf("Get() = (%q, %d), want (%q, %d)", code, attemptsLeft, "123456", 3)
+	}
+}
+
+func TestMemStoreGetNotFound(t *testing.T) {
+	s := NewMemStore(time.Hour)
+	defer s.Close()
+
+	if _, _, err := s.Get(context.Background(), "13800138000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemStoreGetExpired(t *testing.T) {
+	s := NewMemStore(time.Hour)
+	defer s.Close()
+	ctx := context.Background()
+
+	// Put with a negative TTL so the entry is already expired; the
+	// background sweeper runs only every hour, so Get must also check
+	// expiry itself rather than relying on the sweep to have run.
+	if err := s.Put(ctx, "13800138000", "123456", 3, -time.Second); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if _, _, err := s.Get(ctx, "13800138000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemStoreDecrementAttempts(t *testing.T) {
+	s := NewMemStore(time.Hour)
+	defer s.Close()
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "13800138000", "123456", 2, time.Minute); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	left, err := s.DecrementAttempts(ctx, "13800138000")
+	if err != nil {
+		t.Fatalf("DecrementAttempts() error = %v", err)
+	}
+	if left != 1 {
+		t.Errorf("DecrementAttempts() = %d, want 1", left)
+	}
+
+	left, err = s.DecrementAttempts(ctx, "13800138000")
+	if err != nil {
+		t.Fatalf("DecrementAttempts() error = %v", err)
+	}
+	if left != 0 {
+		t.Errorf("DecrementAttempts() = %d, want 0", left)
+	}
+}
+
+func TestMemStoreDecrementAttemptsNotFound(t *testing.T) {
+	s := NewMemStore(time.Hour)
+	defer s.Close()
+
+	if _, err := s.DecrementAttempts(context.Background(), "13800138000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DecrementAttempts() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemStoreDelete(t *testing.T) {
+	s := NewMemStore(time.Hour)
+	defer s.Close()
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "13800138000", "123456", 3, time.Minute); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Delete(ctx, "13800138000"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, _, err := s.Get(ctx, "13800138000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
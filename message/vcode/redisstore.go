@@ -0,0 +1,78 @@
+package vcode
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisStore is a Store backed by Redis, suitable for sharing issued
+// codes across multiple instances. Each phone number is stored as a hash
+// with "code" and "attempts" fields and an expiry set on the whole key.
+type RedisStore struct {
+	client *redis.Client
+	// KeyPrefix is prepended to the phone number to form the Redis key,
+	// e.g. "vcode:" so keys don't collide with unrelated data.
+	KeyPrefix string
+}
+
+// NewRedisStore creates a RedisStore backed by client.
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, KeyPrefix: "vcode:"}
+}
+
+func (s *RedisStore) key(phone string) string {
+	return s.KeyPrefix + phone
+}
+
+// Put implements Store.
+func (s *RedisStore) Put(ctx context.Context, phone, code string, attemptsLeft int, ttl time.Duration) error {
+	key := s.key(phone)
+	pipe := s.client.TxPipeline()
+	pipe.HSet(key, "code", code)
+	pipe.HSet(key, "attempts", strconv.Itoa(attemptsLeft))
+	pipe.Expire(key, ttl)
+	_, err := pipe.Exec()
+	return err
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, phone string) (string, int, error) {
+	vals, err := s.client.HGetAll(s.key(phone)).Result()
+	if err != nil {
+		return "", 0, err
+	}
+	code, ok := vals["code"]
+	if !ok {
+		return "", 0, ErrNotFound
+	}
+	attemptsLeft, err := strconv.Atoi(vals["attempts"])
+	if err != nil {
+		return "", 0, err
+	}
+	return code, attemptsLeft, nil
+}
+
+// DecrementAttempts implements Store.
+func (s *RedisStore) DecrementAttempts(ctx context.Context, phone string) (int, error) {
+	key := s.key(phone)
+	exists, err := s.client.Exists(key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		return 0, ErrNotFound
+	}
+	attemptsLeft, err := s.client.HIncrBy(key, "attempts", -1).Result()
+	if err != nil {
+		return 0, err
+	}
+	return int(attemptsLeft), nil
+}
+
+// Delete implements Store.
+func (s *RedisStore) Delete(ctx context.Context, phone string) error {
+	return s.client.Del(s.key(phone)).Err()
+}
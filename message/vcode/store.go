@@ -0,0 +1,30 @@
+package vcode
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Store.Get and Store.DecrementAttempts when no
+// code is stored for a phone number, either because none was issued or
+// because it already expired.
+var ErrNotFound = errors.New("vcode: code not found")
+
+// Store persists issued verification codes keyed by phone number. A Store
+// implementation does not need to reason about the verification workflow
+// itself (rate limiting, whether a code matches); it only needs to hold
+// (code, attempts) pairs with an expiry.
+type Store interface {
+	// Put stores code for phone, along with the number of verify
+	// attempts allowed before it is rejected, expiring after ttl.
+	Put(ctx context.Context, phone, code string, attemptsLeft int, ttl time.Duration) error
+	// Get returns the code and remaining attempts stored for phone.
+	// It returns ErrNotFound if nothing is stored or it has expired.
+	Get(ctx context.Context, phone string) (code string, attemptsLeft int, err error)
+	// DecrementAttempts decrements and returns the remaining attempts for
+	// phone. It returns ErrNotFound under the same conditions as Get.
+	DecrementAttempts(ctx context.Context, phone string) (attemptsLeft int, err error)
+	// Delete removes phone's stored code, e.g. after a successful verify.
+	Delete(ctx context.Context, phone string) error
+}
@@ -0,0 +1,182 @@
+// Package vcode wraps message.Client's raw SendSMS into a verification
+// code workflow: issue a code, send it by SMS, cache it, and later check
+// a user-supplied code against the cache.
+package vcode
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+// ErrRateLimited is returned by Issue when phone has already been sent
+// MaxSendsPerHour codes within the past hour.
+var ErrRateLimited = errors.New("vcode: rate limited")
+
+// ErrTooManyAttempts is returned by Verify once a code's attempts are
+// exhausted; the code is deleted so a fresh Issue is required.
+var ErrTooManyAttempts = errors.New("vcode: too many attempts")
+
+// DefaultAlphabet is the digit alphabet used when Config.Alphabet is empty.
+const DefaultAlphabet = "0123456789"
+
+// Config controls code generation, expiry and rate limiting.
+type Config struct {
+	// CodeLength is the number of characters in a generated code.
+	// Defaults to 6 if zero.
+	CodeLength int
+	// Alphabet is the set of characters a code is drawn from. Defaults
+	// to DefaultAlphabet if empty.
+	Alphabet string
+	// Expiry is how long an issued code remains valid. Defaults to
+	// 5 minutes if zero.
+	Expiry time.Duration
+	// MaxAttempts is how many wrong Verify calls are allowed before the
+	// code is invalidated. Defaults to 5 if zero.
+	MaxAttempts int
+	// MaxSendsPerHour caps how many codes may be issued to the same
+	// phone number per hour. Defaults to 5 if zero.
+	MaxSendsPerHour int
+}
+
+func (c Config) withDefaults() Config {
+	if c.CodeLength == 0 {
+		c.CodeLength = 6
+	}
+	if c.Alphabet == "" {
+		c.Alphabet = DefaultAlphabet
+	}
+	if c.Expiry == 0 {
+		c.Expiry = 5 * time.Minute
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 5
+	}
+	if c.MaxSendsPerHour == 0 {
+		c.MaxSendsPerHour = 5
+	}
+	return c
+}
+
+// VCodeManager issues and verifies SMS verification codes.
+//
+// Rate limiting is tracked in-process, so a VCodeManager spread across
+// multiple instances without a shared front-end will only enforce
+// MaxSendsPerHour per instance. RedisStore shares issued codes across
+// instances but not the send log, and message.WithRateLimit only throttles
+// the requests of the one Client it is set on.
+type VCodeManager struct {
+	client *message.Client
+	store  Store
+	cfg    Config
+
+	mu      sync.Mutex
+	sendLog map[string][]time.Time
+}
+
+// NewVCodeManager creates a VCodeManager that sends codes through client
+// and caches them in store.
+func NewVCodeManager(client *message.Client, store Store, cfg Config) *VCodeManager {
+	return &VCodeManager{
+		client:  client,
+		store:   store,
+		cfg:     cfg.withDefaults(),
+		sendLog: make(map[string][]time.Time),
+	}
+}
+
+// Issue generates a code, sends it to phone via signName/templateCode
+// rendered as {"code":"..."}, and stores it for a later Verify. It
+// returns ErrRateLimited if phone has exceeded Config.MaxSendsPerHour.
+func (m *VCodeManager) Issue(ctx context.Context, phone, signName, templateCode string) (string, error) {
+	if !m.allowSend(phone) {
+		return "", ErrRateLimited
+	}
+
+	code, err := m.generateCode()
+	if err != nil {
+		return "", err
+	}
+
+	templateParam, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		return "", err
+	}
+	ok, resp, err := m.client.SendSMS(ctx, []string{phone}, signName, templateCode, string(templateParam))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("vcode: send failed: %s: %s", resp.Code, resp.Message)
+	}
+
+	if err := m.store.Put(ctx, phone, code, m.cfg.MaxAttempts, m.cfg.Expiry); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Verify checks code against the one stored for phone. It decrements the
+// remaining attempts on a mismatch and deletes the stored code on
+// success, so each issued code can only be used once.
+func (m *VCodeManager) Verify(ctx context.Context, phone, code string) (bool, error) {
+	stored, attemptsLeft, err := m.store.Get(ctx, phone)
+	if errors.Is(err, ErrNotFound) {
+		return false, ErrNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if attemptsLeft <= 0 {
+		_ = m.store.Delete(ctx, phone)
+		return false, ErrTooManyAttempts
+	}
+
+	if stored == code {
+		return true, m.store.Delete(ctx, phone)
+	}
+
+	if _, err := m.store.DecrementAttempts(ctx, phone); err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	return false, nil
+}
+
+func (m *VCodeManager) allowSend(phone string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-time.Hour)
+	sends := m.sendLog[phone][:0]
+	for _, t := range m.sendLog[phone] {
+		if t.After(cutoff) {
+			sends = append(sends, t)
+		}
+	}
+	if len(sends) >= m.cfg.MaxSendsPerHour {
+		m.sendLog[phone] = sends
+		return false
+	}
+	m.sendLog[phone] = append(sends, time.Now())
+	return true
+}
+
+func (m *VCodeManager) generateCode() (string, error) {
+	alphabet := m.cfg.Alphabet
+	code := make([]byte, m.cfg.CodeLength)
+	buf := make([]byte, m.cfg.CodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		code[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,96 @@
+package vcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVCodeManagerVerifySuccess(t *testing.T) {
+	store := NewMemStore(time.Hour)
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "13800138000", "123456", 3, time.Minute); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	m := NewVCodeManager(nil, store, Config{})
+
+	ok, err := m.Verify(ctx, "13800138000", "123456")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !ok {
+		t.Error("Verify() = false, want true for matching code")
+	}
+
+	// A code is single-use: it must be deleted from the store on success.
+	if _, _, err := store.Get(ctx, "13800138000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("store still has an entry after a successful Verify(): err = %v", err)
+	}
+}
+
+func TestVCodeManagerVerifyWrongCodeDecrementsAttempts(t *testing.T) {
+	store := NewMemStore(time.Hour)
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "13800138000", "123456", 2, time.Minute); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	m := NewVCodeManager(nil, store, Config{})
+
+	ok, err := m.Verify(ctx, "13800138000", "000000")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if ok {
+		t.Error("Verify() = true, want false for mismatched code")
+	}
+
+	_, attemptsLeft, err := store.Get(ctx, "13800138000")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if attemptsLeft != 1 {
+		t.Errorf("attemptsLeft = %d, want 1 after one wrong Verify()", attemptsLeft)
+	}
+}
+
+func TestVCodeManagerVerifyTooManyAttempts(t *testing.T) {
+	store := NewMemStore(time.Hour)
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "13800138000", "123456", 0, time.Minute); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	m := NewVCodeManager(nil, store, Config{})
+
+	ok, err := m.Verify(ctx, "13800138000", "123456")
+	if !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("Verify() error = %v, want ErrTooManyAttempts", err)
+	}
+	if ok {
+		t.Error("Verify() = true, want false once attempts are exhausted")
+	}
+
+	if _, _, err := store.Get(ctx, "13800138000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("store still has an entry after attempts were exhausted: err = %v", err)
+	}
+}
+
+func TestVCodeManagerVerifyNotFound(t *testing.T) {
+	store := NewMemStore(time.Hour)
+	defer store.Close()
+
+	m := NewVCodeManager(nil, store, Config{})
+
+	if _, err := m.Verify(context.Background(), "13800138000", "123456"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Verify() error = %v, want ErrNotFound for a phone with no issued code", err)
+	}
+}